@@ -0,0 +1,220 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// parseConfigFiles parses the Terraform configuration files at the root of the given filesystem. Files that fail to
+// parse are omitted from the result, and their errors are reported in the returned diagnostics.
+func parseConfigFiles(root afero.Fs) ([]*hcl.File, hcl.Diagnostics) {
+	return parseConfigDir(root, ".")
+}
+
+// parseConfigDir parses the Terraform configuration files (*.tf and *.tf.json) in the given directory of root. dir is
+// a slash-separated path relative to the root of the filesystem, and the names of the returned files are relative to
+// the root as well. Files that fail to parse are omitted from the result, and their errors are reported in the
+// returned diagnostics.
+func parseConfigDir(root afero.Fs, dir string) ([]*hcl.File, hcl.Diagnostics) {
+	entries, err := afero.ReadDir(root, "/"+dir)
+	if err != nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "failed to read the source directory",
+			Detail:   err.Error(),
+		}}
+	}
+
+	var files []*hcl.File
+	var diagnostics hcl.Diagnostics
+	for _, entry := range entries {
+		isJSON := strings.HasSuffix(entry.Name(), ".tf.json")
+		if entry.IsDir() || (filepath.Ext(entry.Name()) != ".tf" && !isJSON) {
+			continue
+		}
+
+		name := path.Join(dir, entry.Name())
+		contents, err := afero.ReadFile(root, "/"+name)
+		if err != nil {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("failed to read file %s", name),
+				Detail:   err.Error(),
+			})
+			continue
+		}
+
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		if isJSON {
+			file, diags = hcljson.Parse(contents, name)
+		} else {
+			file, diags = hclsyntax.ParseConfig(contents, name, hcl.Pos{Line: 1, Column: 1})
+		}
+		diagnostics = append(diagnostics, diags...)
+		if !diags.HasErrors() {
+			files = append(files, file)
+		}
+	}
+	return files, diagnostics
+}
+
+// terraformSchema describes the parts of the top-level terraform block that are inspected before conversion.
+var terraformSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "terraform"}},
+}
+
+// requiredVersionSchema describes the required_version attribute of a terraform block.
+var requiredVersionSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "required_version"}},
+}
+
+// checkRequiredVersion returns an error diagnostic for each required_version constraint in the given configuration
+// files that is not satisfied by any of the targeted Terraform versions. targetName describes the targets in
+// diagnostics. Constraints that cannot be evaluated are ignored, as they will be reported by the converter itself.
+func checkRequiredVersion(files []*hcl.File, targets []*version.Version, targetName string) hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(terraformSchema)
+		for _, block := range content.Blocks {
+			attrs, _, _ := block.Body.PartialContent(requiredVersionSchema)
+			attr, ok := attrs.Attributes["required_version"]
+			if !ok {
+				continue
+			}
+			value, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !value.Type().Equals(cty.String) || !value.IsKnown() || value.IsNull() {
+				continue
+			}
+			constraints, err := version.NewConstraint(value.AsString())
+			if err != nil || checkAny(constraints, targets) {
+				continue
+			}
+
+			detail := fmt.Sprintf("the configuration's required_version constraint %q is not satisfied by Terraform %s",
+				value.AsString(), targetName)
+			if suggestion := suggestTerraformVersion(constraints); suggestion != "" {
+				detail += fmt.Sprintf("; pass --terraform-version=%s to convert it", suggestion)
+			} else {
+				detail += "; no Terraform version supported by tf2pulumi satisfies it"
+			}
+
+			rng := attr.Expr.Range()
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("configuration requires Terraform %s", value.AsString()),
+				Detail:   detail,
+				Subject:  &rng,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// checkAny returns true if any of the given versions satisfies the constraints.
+func checkAny(constraints version.Constraints, versions []*version.Version) bool {
+	for _, v := range versions {
+		if constraints.Check(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// supportedTerraformVersions are the --terraform-version values that are suggested when a configuration's
+// required_version is not satisfied, in order of preference. 0.11 configurations are read by the TF11 loader, and
+// all later versions by the TF12 pipeline.
+var supportedTerraformVersions = []string{
+	"11", "12", "13", "14", "15", "1.0", "1.1", "1.2", "1.3", "1.4", "1.5",
+}
+
+// suggestTerraformVersion returns the first supported --terraform-version value that satisfies the given constraints,
+// or the empty string if there is none.
+func suggestTerraformVersion(constraints version.Constraints) string {
+	for _, v := range supportedTerraformVersions {
+		if targets, _, err := parseTerraformVersion(v); err == nil && checkAny(constraints, targets) {
+			return v
+		}
+	}
+	return ""
+}
+
+// maxPatchVersion bounds the patch releases considered for a version without a patch number. No minor release of
+// Terraform has had more than a few dozen patch releases.
+const maxPatchVersion = 99
+
+// parseTerraformVersion parses the value of the --terraform-version flag into the Terraform versions it targets and a
+// description of those versions. In addition to full versions, the flag accepts the short minor versions used for
+// pre-1.0 releases, e.g. "11" for Terraform 0.11. A short minor version targets every patch release of that minor
+// version, so "12" satisfies both ">= 0.12.26" and "< 0.12.5". Likewise, a version without a patch number, e.g. "1.5",
+// targets every patch release of that version.
+func parseTerraformVersion(v string) ([]*version.Version, string, error) {
+	major, minor := "0", v
+	if parts := strings.Split(v, "."); len(parts) == 2 {
+		major, minor = parts[0], parts[1]
+	}
+	if majorNumber, err := strconv.Atoi(major); err == nil {
+		if minorNumber, err := strconv.Atoi(minor); err == nil && (majorNumber > 0 || minorNumber >= 11) {
+			versions := make([]*version.Version, 0, maxPatchVersion+1)
+			for patch := 0; patch <= maxPatchVersion; patch++ {
+				versions = append(versions, version.Must(version.NewVersion(
+					fmt.Sprintf("%d.%d.%d", majorNumber, minorNumber, patch))))
+			}
+			return versions, fmt.Sprintf("%d.%d.x", majorNumber, minorNumber), nil
+		}
+	}
+
+	parsed, err := version.NewVersion(v)
+	if err != nil {
+		return nil, "", err
+	}
+	return []*version.Version{parsed}, parsed.String(), nil
+}
+
+// checkRoot checks the configuration in the given root for required_version constraints that are not satisfied by
+// the targeted Terraform version, writing any violations to stderr. Parse errors are left for the converter to report.
+func checkRoot(root afero.Fs, targets []*version.Version, targetName string) error {
+	files, _ := parseConfigFiles(root)
+	diags := checkRequiredVersion(files, targets, targetName)
+	if len(diags) == 0 {
+		return nil
+	}
+
+	if !collectJSONDiagnostics(diags) {
+		fileMap := make(map[string]*hcl.File, len(files))
+		for _, file := range files {
+			if body, ok := file.Body.(*hclsyntax.Body); ok {
+				fileMap[body.SrcRange.Filename] = file
+			}
+		}
+		if err := hcl.NewDiagnosticTextWriter(os.Stderr, fileMap, 0, true).WriteDiagnostics(diags); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("the configuration's required_version is not satisfied by Terraform %s", targetName)
+}
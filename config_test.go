@@ -0,0 +1,72 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRequiredVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		terraformVersion string
+		constraint       string
+		ok               bool
+		suggestion       string
+	}{
+		{"12", ">= 0.12.26", true, ""},
+		{"12", "< 0.12.5", true, ""},
+		{"12", ">= 0.13", false, "13"},
+		{"11", ">= 0.12", false, "12"},
+		{"11", ">= 1.0", false, "1.0"},
+		{"11", "~> 1.5.3", false, "1.5"},
+		{"0.12.20", ">= 0.12.26", false, "12"},
+		{"1.5.0", ">= 1.0", true, ""},
+		{"1.5", "~> 1.5.3", true, ""},
+		{"12", "< 0.11", false, ""},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.terraformVersion+" "+c.constraint, func(t *testing.T) {
+			t.Parallel()
+
+			targets, targetName, err := parseTerraformVersion(c.terraformVersion)
+			require.NoError(t, err)
+
+			src := "terraform {\n  required_version = \"" + c.constraint + "\"\n}\n"
+			file, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors())
+
+			diags = checkRequiredVersion([]*hcl.File{file}, targets, targetName)
+			if c.ok {
+				assert.Empty(t, diags)
+			} else {
+				require.Len(t, diags, 1)
+				assert.Contains(t, diags[0].Detail, c.constraint)
+				if c.suggestion != "" {
+					assert.Contains(t, diags[0].Detail, "--terraform-version="+c.suggestion)
+				} else {
+					assert.Contains(t, diags[0].Detail, "no Terraform version supported by tf2pulumi satisfies it")
+				}
+			}
+		})
+	}
+}
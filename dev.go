@@ -0,0 +1,258 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/convert"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// snapshotFile describes the generated program and baseline files for a single language in a test case. The names
+// match those used by the test harness in tests/terraform.
+type snapshotFile struct {
+	program  string
+	baseline string
+}
+
+// snapshotFiles maps each language covered by the test corpus to its snapshot files.
+var snapshotFiles = map[string]snapshotFile{
+	"python":     {program: "__main__.py", baseline: "__main__.base.py"},
+	"typescript": {program: "index.ts", baseline: "index.base.ts"},
+}
+
+// newDevCommand returns the command that groups tools for maintaining tf2pulumi and its test corpora.
+func newDevCommand(opts *convert.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for maintaining tf2pulumi and its test corpora",
+	}
+	cmd.AddCommand(newUpdateSnapshotsCommand(opts))
+	return cmd
+}
+
+// newUpdateSnapshotsCommand returns the command that regenerates the baseline files of a test corpus.
+func newUpdateSnapshotsCommand(opts *convert.Options) *cobra.Command {
+	var filterName string
+	var check, create bool
+
+	cmd := &cobra.Command{
+		Use:   "update-snapshots dir...",
+		Short: "Regenerate the expected outputs of a test corpus",
+		Long: `Regenerate the expected outputs of a test corpus.
+
+Each test case is a directory containing Terraform configuration. The case is
+converted to each supported language, and the result is compared against the
+case's baseline file (index.base.ts, __main__.base.py). Baselines that differ
+are printed as a diff and updated in place.
+
+A directory ending in "/..." names all of the test cases beneath it.`,
+		Args: cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dirs, err := testCaseDirs(args)
+			if err != nil {
+				return err
+			}
+
+			languages := make([]string, 0, len(snapshotFiles))
+			for language := range snapshotFiles {
+				languages = append(languages, language)
+			}
+			sort.Strings(languages)
+
+			changed, failed := 0, 0
+			for _, dir := range dirs {
+				for _, language := range languages {
+					files := snapshotFiles[language]
+					baselinePath := filepath.Join(dir, files.baseline)
+
+					baseline, err := ioutil.ReadFile(baselinePath)
+					switch {
+					case os.IsNotExist(err) && !create:
+						continue
+					case err != nil && !os.IsNotExist(err):
+						return err
+					}
+
+					program, err := generateSnapshot(*opts, dir, language, files.program, filterName)
+					if err != nil {
+						failed++
+						if !collectJSONMessage(hcl.DiagError, fmt.Sprintf("%v: %v", baselinePath, err), "") {
+							fmt.Fprintf(os.Stderr, "%v: %v\n", baselinePath, err)
+						}
+						continue
+					}
+					if string(program) == string(baseline) {
+						continue
+					}
+
+					changed++
+					diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+						A:        difflib.SplitLines(string(baseline)),
+						B:        difflib.SplitLines(string(program)),
+						FromFile: baselinePath,
+						ToFile:   baselinePath,
+						Context:  3,
+					})
+					if err != nil {
+						return err
+					}
+					fmt.Print(diff)
+
+					if !check {
+						//nolint:gosec // baselines are checked-in test data
+						if err := ioutil.WriteFile(baselinePath, program, 0o644); err != nil {
+							return err
+						}
+					}
+				}
+			}
+
+			switch {
+			case failed != 0:
+				return fmt.Errorf("failed to generate %d snapshot(s)", failed)
+			case check && changed != 0:
+				return fmt.Errorf("%d snapshot(s) are out of date", changed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filterName, "filter-resource-names", "name",
+		"the resource property to remove when generating snapshots; matches the test harness's default")
+	cmd.Flags().BoolVar(&check, "check", false,
+		"report out-of-date snapshots and fail instead of updating them")
+	cmd.Flags().BoolVar(&create, "create", false,
+		"create baselines for languages that do not have one yet")
+	return cmd
+}
+
+// testCaseMarkers are the files that mark a directory as a test case of the harness in tests/terraform, in addition
+// to the baselines listed in snapshotFiles. Directories without a marker, such as local modules, are not test cases.
+var testCaseMarkers = []string{"package.json", "Pulumi.yaml"}
+
+// testCaseDirs expands the given arguments into the list of test case directories they name. A directory ending in
+// "/..." is expanded to every directory beneath it (including itself) that contains Terraform configuration and a
+// test case marker. Each directory is listed once, in the order in which it is first named.
+func testCaseDirs(args []string) ([]string, error) {
+	var dirs []string
+	seen := map[string]bool{}
+	add := func(dir string) {
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, arg := range args {
+		if !strings.HasSuffix(arg, "/...") {
+			add(arg)
+			continue
+		}
+
+		root := strings.TrimSuffix(arg, "/...")
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			isCase, err := isTestCaseDir(path)
+			if err != nil {
+				return err
+			}
+			if isCase {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// isTestCaseDir returns true if the given directory contains Terraform configuration and a test case marker or
+// baseline.
+func isTestCaseDir(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	markers := map[string]bool{}
+	for _, marker := range testCaseMarkers {
+		markers[marker] = true
+	}
+	for _, files := range snapshotFiles {
+		markers[files.baseline] = true
+	}
+
+	hasConfig, hasMarker := false, false
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		hasConfig = hasConfig || filepath.Ext(entry.Name()) == ".tf"
+		hasMarker = hasMarker || markers[entry.Name()]
+	}
+	return hasConfig && hasMarker, nil
+}
+
+// generateSnapshot converts the test case in the given directory to the given language and returns the contents of
+// the generated program file. The options mirror those passed to tf2pulumi by the test harness.
+func generateSnapshot(opts convert.Options, dir, language, program, filterName string) ([]byte, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Root = afero.NewBasePathFs(afero.NewOsFs(), abs)
+	opts.TargetLanguage = language
+	opts.FilterResourceNames = filterName != ""
+	opts.ResourceNameProperty = filterName
+	opts.AnnotateNodesWithLocations = true
+
+	files, diags, err := convert.Convert(opts)
+	if err := writeDiagnostics(diags); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if diags.All.HasErrors() {
+		return nil, fmt.Errorf("conversion failed with %d diagnostic(s)", len(diags.All))
+	}
+
+	contents, ok := files[program]
+	if !ok {
+		return nil, fmt.Errorf("the conversion did not produce %v", program)
+	}
+	return contents, nil
+}
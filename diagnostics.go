@@ -0,0 +1,133 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+const (
+	diagnosticsFormatText = "text"
+	diagnosticsFormatJSON = "json"
+)
+
+// diagnosticsFormat is the format in which diagnostics are reported. Text diagnostics are written to stderr as they
+// are produced. JSON diagnostics are collected and written as a single array by flushJSONDiagnostics.
+var diagnosticsFormat = diagnosticsFormatText
+
+// diagnosticsFile is the file to which JSON diagnostics are written. If empty, they are written to stderr.
+var diagnosticsFile string
+
+// jsonPos describes a position in a source file.
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// jsonDiagnostic is the JSON representation of a single diagnostic.
+type jsonDiagnostic struct {
+	Severity string   `json:"severity"`
+	Summary  string   `json:"summary"`
+	Detail   string   `json:"detail,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Start    *jsonPos `json:"start,omitempty"`
+	End      *jsonPos `json:"end,omitempty"`
+}
+
+// jsonDiagnostics holds the diagnostics collected for output in JSON format.
+var jsonDiagnostics = []jsonDiagnostic{}
+
+// validateDiagnosticsFormat returns an error if the requested diagnostics format is not supported.
+func validateDiagnosticsFormat() error {
+	switch diagnosticsFormat {
+	case diagnosticsFormatText, diagnosticsFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported diagnostics format %q; expected %q or %q", diagnosticsFormat,
+			diagnosticsFormatText, diagnosticsFormatJSON)
+	}
+}
+
+// collectJSONDiagnostics records the given diagnostics for output in JSON format. It returns false if diagnostics are
+// being reported as text, in which case the caller is responsible for writing them.
+func collectJSONDiagnostics(diags hcl.Diagnostics) bool {
+	if diagnosticsFormat != diagnosticsFormatJSON {
+		return false
+	}
+
+	for _, diag := range diags {
+		severity := "error"
+		if diag.Severity == hcl.DiagWarning {
+			severity = "warning"
+		}
+
+		d := jsonDiagnostic{Severity: severity, Summary: diag.Summary, Detail: diag.Detail}
+		if diag.Subject != nil {
+			d.File = diag.Subject.Filename
+			d.Start = &jsonPos{Line: diag.Subject.Start.Line, Column: diag.Subject.Start.Column}
+			d.End = &jsonPos{Line: diag.Subject.End.Line, Column: diag.Subject.End.Column}
+		}
+		jsonDiagnostics = append(jsonDiagnostics, d)
+	}
+	return true
+}
+
+// collectJSONError records the given error as an error diagnostic for output in JSON format. It returns false if
+// diagnostics are being reported as text, in which case the caller is responsible for reporting the error.
+func collectJSONError(err error) bool {
+	if diagnosticsFormat != diagnosticsFormatJSON {
+		return false
+	}
+	jsonDiagnostics = append(jsonDiagnostics, jsonDiagnostic{Severity: "error", Summary: err.Error()})
+	return true
+}
+
+// collectJSONMessage records a diagnostic that has no source location for output in JSON format. It returns false if
+// diagnostics are being reported as text, in which case the caller is responsible for writing the message.
+func collectJSONMessage(severity hcl.DiagnosticSeverity, summary, detail string) bool {
+	return collectJSONDiagnostics(hcl.Diagnostics{{Severity: severity, Summary: summary, Detail: detail}})
+}
+
+// reportWarning writes the given warning to stderr, or collects it if diagnostics are being reported as JSON.
+func reportWarning(summary string) {
+	if !collectJSONMessage(hcl.DiagWarning, summary, "") {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", summary)
+	}
+}
+
+// flushJSONDiagnostics writes the diagnostics collected by collectJSONDiagnostics as a JSON array to the diagnostics
+// file or to stderr. Nothing is written if diagnostics are being reported as text.
+func flushJSONDiagnostics() error {
+	if diagnosticsFormat != diagnosticsFormatJSON {
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(jsonDiagnostics, "", "    ")
+	if err != nil {
+		return err
+	}
+	bytes = append(bytes, '\n')
+
+	if diagnosticsFile != "" {
+		return ioutil.WriteFile(diagnosticsFile, bytes, 0600)
+	}
+	_, err = os.Stderr.Write(bytes)
+	return err
+}
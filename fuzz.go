@@ -0,0 +1,216 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/convert"
+	"github.com/spf13/cobra"
+)
+
+// newFuzzCommand returns the command that runs the converter over a corpus of Terraform configuration files. Each file
+// is converted on its own; panics raised by the converter are recovered and reported as diagnostics, and the input
+// that caused each panic is minimized and written out for reporting.
+func newFuzzCommand(opts *convert.Options) *cobra.Command {
+	var corpus, crashers string
+
+	cmd := &cobra.Command{
+		Use:   "fuzz",
+		Short: "Run the converter over a corpus of Terraform configuration files",
+		Long: `Run the converter over every .tf file found under the corpus directory.
+
+Each file is converted independently. Conversions that panic are reported,
+and a minimized version of each input that caused a panic is written to the
+crashers directory.`,
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if corpus == "" {
+				return errors.New("--corpus must be specified")
+			}
+
+			paths, err := corpusFiles(corpus)
+			if err != nil {
+				return err
+			}
+
+			failed, panicked := 0, 0
+			for _, path := range paths {
+				contents, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+
+				diags, err := convertSafely(*opts, contents)
+				switch {
+				case err != nil:
+					failed++
+					if !collectJSONMessage(hcl.DiagError, fmt.Sprintf("%v: %v", path, err), "") {
+						fmt.Fprintf(os.Stderr, "%v: error: %v\n", path, err)
+					}
+				case isPanic(diags):
+					panicked++
+					summary := fmt.Sprintf("%v: %v", path, diags[0].Summary)
+					if !collectJSONMessage(hcl.DiagError, summary, diags[0].Detail) {
+						fmt.Fprintf(os.Stderr, "%v\n%v\n", summary, diags[0].Detail)
+					}
+
+					minimized := minimizeCrasher(*opts, contents, diags[0].Summary)
+					if err := writeCrasher(crashers, corpus, path, minimized); err != nil {
+						return err
+					}
+				case diags.HasErrors():
+					failed++
+					summary := fmt.Sprintf("%v: failed with %d diagnostic(s)", path, len(diags))
+					if !collectJSONMessage(hcl.DiagError, summary, "") {
+						fmt.Fprintf(os.Stderr, "%v\n", summary)
+					}
+				}
+			}
+
+			if diagnosticsFormat != diagnosticsFormatJSON {
+				fmt.Fprintf(os.Stderr, "\n%d file(s): %d converted, %d failed, %d panicked\n",
+					len(paths), len(paths)-failed-panicked, failed, panicked)
+			}
+			if panicked != 0 {
+				return fmt.Errorf("%d of %d file(s) caused the converter to panic; minimized inputs were written to %v",
+					panicked, len(paths), crashers)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&corpus, "corpus", "",
+		"the directory containing the Terraform configuration files to convert")
+	cmd.Flags().StringVar(&crashers, "crashers", "crashers",
+		"the directory to which minimized inputs that caused a panic are written")
+	return cmd
+}
+
+// panicSummaryPrefix prefixes the summary of the diagnostic that records a converter panic.
+const panicSummaryPrefix = "the converter panicked: "
+
+// isPanic returns true if the given diagnostics record a converter panic.
+func isPanic(diags hcl.Diagnostics) bool {
+	return len(diags) == 1 && strings.HasPrefix(diags[0].Summary, panicSummaryPrefix)
+}
+
+// corpusFiles returns the paths of all .tf files under the given directory in lexical order.
+func corpusFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".tf" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// convertSafely converts a single configuration file held in memory. If the converter panics, the panic is recovered
+// and returned as a single error diagnostic that carries the panic's stack trace.
+func convertSafely(opts convert.Options, contents []byte) (diags hcl.Diagnostics, err error) {
+	root, err := memoryRoot(contents)
+	if err != nil {
+		return nil, err
+	}
+	opts.Root = root
+
+	defer func() {
+		if v := recover(); v != nil {
+			diags, err = hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s%v", panicSummaryPrefix, v),
+				Detail:   string(debug.Stack()),
+			}}, nil
+		}
+	}()
+
+	_, d, err := convert.Convert(opts)
+	return d.All, err
+}
+
+// minimizeCrasher reduces a configuration that causes the converter to panic by repeatedly removing top-level blocks
+// and attributes for as long as the converter still panics with the same summary. If the configuration cannot be
+// parsed, it is returned as-is.
+func minimizeCrasher(opts convert.Options, contents []byte, summary string) []byte {
+	file, diags := hclsyntax.ParseConfig(contents, memoryFileName, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return contents
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	var items []hcl.Range
+	for _, attr := range body.Attributes {
+		items = append(items, attr.SrcRange)
+	}
+	for _, block := range body.Blocks {
+		items = append(items, block.Range())
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Start.Byte < items[j].Start.Byte })
+
+	render := func(items []hcl.Range) []byte {
+		var buf bytes.Buffer
+		for _, item := range items {
+			buf.Write(item.SliceBytes(contents))
+			buf.WriteString("\n\n")
+		}
+		return buf.Bytes()
+	}
+
+	for i := 0; i < len(items); {
+		candidate := append(append([]hcl.Range{}, items[:i]...), items[i+1:]...)
+		diags, err := convertSafely(opts, render(candidate))
+		if err == nil && isPanic(diags) && diags[0].Summary == summary {
+			items = candidate
+			continue
+		}
+		i++
+	}
+	return render(items)
+}
+
+// writeCrasher writes a minimized crashing input to the crashers directory. The file is named after the input's path
+// relative to the corpus directory.
+func writeCrasher(crashers, corpus, path string, contents []byte) error {
+	rel, err := filepath.Rel(corpus, path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(crashers, 0700); err != nil {
+		return err
+	}
+	name := strings.ReplaceAll(rel, string(filepath.Separator), "_")
+	return ioutil.WriteFile(filepath.Join(crashers, name), contents, 0600)
+}
@@ -0,0 +1,174 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/spf13/afero"
+)
+
+// ignoreFileNames are the names of the files at the root of a source directory that list paths to skip during
+// conversion. Both files use gitignore syntax, and their patterns are combined.
+var ignoreFileNames = []string{".terraformignore", ".tf2pulumiignore"}
+
+// newIgnoreFs returns a filesystem that hides the paths in root that are matched by the patterns in root's ignore
+// files or by the given directory patterns. If there is nothing to ignore, root is returned as-is.
+func newIgnoreFs(root afero.Fs, excludeDirs []string) (afero.Fs, error) {
+	var patterns []gitignore.Pattern
+	for _, name := range ignoreFileNames {
+		contents, err := afero.ReadFile(root, "/"+name)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(contents))
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), " \t\r")
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			patterns = append(patterns, gitignore.ParsePattern(line, nil))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Directory exclusions are gitignore patterns that only match directories.
+	for _, dir := range excludeDirs {
+		if !strings.HasSuffix(dir, "/") {
+			dir += "/"
+		}
+		patterns = append(patterns, gitignore.ParsePattern(dir, nil))
+	}
+
+	if len(patterns) == 0 {
+		return root, nil
+	}
+	return &ignoreFs{Fs: root, matcher: gitignore.NewMatcher(patterns)}, nil
+}
+
+// ignoreFs is a read-only view of a filesystem that hides ignored paths. Ignored paths do not appear in directory
+// listings, and attempts to open or stat them fail as if they did not exist.
+type ignoreFs struct {
+	afero.Fs
+
+	matcher gitignore.Matcher
+}
+
+// ignored returns true if the given path or any of its parent directories is ignored.
+func (fs *ignoreFs) ignored(name string, isDir bool) bool {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return false
+	}
+
+	parts := strings.Split(name, "/")
+	for i := 1; i <= len(parts); i++ {
+		if fs.matcher.Match(parts[:i], i < len(parts) || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *ignoreFs) Name() string {
+	return "ignoreFs"
+}
+
+func (fs *ignoreFs) Stat(name string) (os.FileInfo, error) {
+	info, err := fs.Fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if fs.ignored(name, info.IsDir()) {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return info, nil
+}
+
+func (fs *ignoreFs) Open(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (fs *ignoreFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	f, err := fs.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if fs.ignored(name, info.IsDir()) {
+		_ = f.Close()
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return &ignoreFile{File: f, fs: fs, name: name}, nil
+}
+
+// ignoreFile is a file opened from an ignoreFs. Its directory listings omit ignored entries.
+type ignoreFile struct {
+	afero.File
+
+	fs   *ignoreFs
+	name string
+}
+
+func (f *ignoreFile) Readdir(count int) ([]os.FileInfo, error) {
+	var result []os.FileInfo
+	for {
+		n := count
+		if count > 0 {
+			n = count - len(result)
+		}
+
+		infos, err := f.File.Readdir(n)
+		for _, info := range infos {
+			if !f.fs.ignored(path.Join(f.name, info.Name()), info.IsDir()) {
+				result = append(result, info)
+			}
+		}
+
+		// If the caller asked for a limited number of entries, keep reading until that many unignored entries
+		// have been found or the directory is exhausted.
+		if err != nil || count <= 0 || len(result) >= count {
+			if err == io.EOF && len(result) != 0 {
+				err = nil
+			}
+			return result, err
+		}
+	}
+}
+
+func (f *ignoreFile) Readdirnames(count int) ([]string, error) {
+	infos, err := f.Readdir(count)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, err
+}
@@ -0,0 +1,125 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIgnoreTestFs returns an in-memory filesystem that holds the given files, keyed by path.
+func newIgnoreTestFs(t *testing.T, files map[string]string) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for name, contents := range files {
+		require.NoError(t, afero.WriteFile(fs, name, []byte(contents), 0600))
+	}
+	return fs
+}
+
+// readDirNames returns the sorted names of the entries in the given directory.
+func readDirNames(t *testing.T, fs afero.Fs, dir string) []string {
+	infos, err := afero.ReadDir(fs, dir)
+	require.NoError(t, err)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestNewIgnoreFsWithoutPatterns(t *testing.T) {
+	t.Parallel()
+
+	root := newIgnoreTestFs(t, map[string]string{"/main.tf": ""})
+	fs, err := newIgnoreFs(root, nil)
+	require.NoError(t, err)
+	assert.Equal(t, root, fs)
+}
+
+func TestIgnoreFs(t *testing.T) {
+	t.Parallel()
+
+	root := newIgnoreTestFs(t, map[string]string{
+		"/.terraformignore":    "# comment\n\n*.bak\n!keep.bak\nmodules/\n",
+		"/.tf2pulumiignore":    "secret.tf\n",
+		"/main.tf":             "",
+		"/secret.tf":           "",
+		"/old.bak":             "",
+		"/keep.bak":            "",
+		"/modules/vpc/main.tf": "",
+		"/network/main.tf":     "",
+		"/network/old.bak":     "",
+		"/network/secret.tf":   "",
+	})
+	fs, err := newIgnoreFs(root, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{".terraformignore", ".tf2pulumiignore", "keep.bak", "main.tf", "network"},
+		readDirNames(t, fs, "/"))
+	assert.Equal(t, []string{"main.tf"}, readDirNames(t, fs, "/network"))
+
+	for _, name := range []string{"/secret.tf", "/old.bak", "/modules", "/modules/vpc/main.tf", "/network/old.bak"} {
+		_, err := fs.Stat(name)
+		assert.True(t, os.IsNotExist(err), "stat %v: %v", name, err)
+		_, err = fs.Open(name)
+		assert.True(t, os.IsNotExist(err), "open %v: %v", name, err)
+	}
+	for _, name := range []string{"/main.tf", "/keep.bak", "/network/main.tf"} {
+		_, err := fs.Stat(name)
+		assert.NoError(t, err, "stat %v", name)
+	}
+}
+
+func TestIgnoreFsExcludeDirs(t *testing.T) {
+	t.Parallel()
+
+	root := newIgnoreTestFs(t, map[string]string{
+		"/main.tf":              "",
+		"/examples/main.tf":     "",
+		"/test/fixtures/a.tf":   "",
+		"/network/test/main.tf": "",
+		"/vendor":               "not a directory",
+	})
+
+	// Exclusions match directories with or without a trailing slash, but never files.
+	fs, err := newIgnoreFs(root, []string{"examples", "test/", "vendor"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"main.tf", "network", "vendor"}, readDirNames(t, fs, "/"))
+	assert.Equal(t, []string{}, readDirNames(t, fs, "/network"))
+
+	_, err = fs.Stat("/test/fixtures/a.tf")
+	assert.True(t, os.IsNotExist(err))
+	_, err = fs.Stat("/vendor")
+	assert.NoError(t, err)
+}
+
+func TestSourceRootNotADirectory(t *testing.T) {
+	t.Parallel()
+
+	// A source path that is not a directory is reported by convertRoots, not while reading ignore files.
+	path := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+
+	_, err := sourceRoot(path, []string{"examples"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,214 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/convert"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/il"
+	"github.com/spf13/cobra"
+)
+
+// stateV4 is the subset of a version 4 Terraform state file that is needed to import its resources. See
+// https://github.com/hashicorp/terraform/blob/v1.5.0/internal/states/statefile/version4.go.
+type stateV4 struct {
+	Version   uint64            `json:"version"`
+	Resources []resourceStateV4 `json:"resources"`
+}
+
+// resourceStateV4 describes a single resource in a version 4 Terraform state file.
+type resourceStateV4 struct {
+	Module         string                  `json:"module,omitempty"`
+	Mode           string                  `json:"mode"`
+	Type           string                  `json:"type"`
+	Name           string                  `json:"name"`
+	EachMode       string                  `json:"each,omitempty"`
+	ProviderConfig string                  `json:"provider"`
+	Instances      []instanceObjectStateV4 `json:"instances"`
+}
+
+// instanceObjectStateV4 describes a single instance of a resource in a version 4 Terraform state file.
+type instanceObjectStateV4 struct {
+	IndexKey   interface{}            `json:"index_key,omitempty"`
+	Deposed    string                 `json:"deposed,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// importFile is the file format accepted by `pulumi import --file`.
+type importFile struct {
+	Resources []importResource `json:"resources"`
+}
+
+// importResource describes a single resource to import.
+type importResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// importIDs maps Terraform resource types whose import ID is not the value of their id attribute to functions that
+// compute the import ID from the resource's attributes.
+var importIDs = map[string]func(attrs map[string]interface{}) string{
+	"aws_route": func(attrs map[string]interface{}) string {
+		return fmt.Sprintf("%v_%v", attrs["route_table_id"], attrs["destination_cidr_block"])
+	},
+	"aws_route_table_association": func(attrs map[string]interface{}) string {
+		return fmt.Sprintf("%v/%v", attrs["subnet_id"], attrs["route_table_id"])
+	},
+	"aws_iam_role_policy_attachment": func(attrs map[string]interface{}) string {
+		return fmt.Sprintf("%v/%v", attrs["role"], attrs["policy_arn"])
+	},
+}
+
+// providerAddressRegexp matches the provider address in a resource's provider configuration, e.g.
+// `provider["registry.terraform.io/hashicorp/aws"].west`.
+var providerAddressRegexp = regexp.MustCompile(`^provider\["([^"]+)"\]`)
+
+// newImportCommand returns the command that generates a `pulumi import` file from a Terraform state file.
+func newImportCommand(opts *convert.Options) *cobra.Command {
+	var statePath, outPath string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate a Pulumi import file from a Terraform state file",
+		Long: `Generate a Pulumi import file from a Terraform state file.
+
+Each managed resource in the state is mapped to the Pulumi resource that
+tf2pulumi generates for it, and its ID is recorded so that the resource can
+be adopted by running 'pulumi import --file' instead of being recreated.
+Resources that belong to child modules are skipped.`,
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if statePath == "" {
+				return errors.New("--state must be specified")
+			}
+
+			contents, err := ioutil.ReadFile(statePath)
+			if err != nil {
+				return err
+			}
+			var state stateV4
+			if err := json.Unmarshal(contents, &state); err != nil {
+				return fmt.Errorf("%v: %w", statePath, err)
+			}
+			if state.Version != 4 {
+				return fmt.Errorf("%v: unsupported state version %d; only version 4 state files are supported",
+					statePath, state.Version)
+			}
+
+			source := opts.ProviderInfoSource
+			if source == nil {
+				source = il.PluginProviderInfoSource
+			}
+			file := importResources(il.NewCachingProviderInfoSource(source), state)
+
+			out := io.Writer(os.Stdout)
+			if outPath != "" {
+				f, err := os.Create(outPath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "    ")
+			return encoder.Encode(file)
+		},
+	}
+
+	cmd.Flags().StringVar(&statePath, "state", "",
+		"the Terraform state file to read")
+	cmd.Flags().StringVar(&outPath, "out", "",
+		"the file to write the import file to; defaults to stdout")
+	return cmd
+}
+
+// importResources returns the Pulumi import file that adopts the managed resources in the given state. Resources that
+// cannot be mapped to a Pulumi type are reported as warnings and omitted.
+func importResources(source il.ProviderInfoSource, state stateV4) *importFile {
+	file := &importFile{Resources: []importResource{}}
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+
+		address := resource.Type + "." + resource.Name
+		if resource.Module != "" {
+			reportWarning(fmt.Sprintf("skipping %v.%v: resources in modules are not supported", resource.Module,
+				address))
+			continue
+		}
+
+		typ, err := importType(source, resource)
+		if err != nil {
+			reportWarning(fmt.Sprintf("skipping %v: %v", address, err))
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			if instance.Deposed != "" {
+				continue
+			}
+
+			// Generated programs use the Terraform name as each resource's logical name. Resources that use count or
+			// for_each are generated as one Pulumi resource per instance, named after the instance's index or key.
+			name := resource.Name
+			if resource.EachMode != "" && instance.IndexKey != nil {
+				name = fmt.Sprintf("%v-%v", name, instance.IndexKey)
+			}
+
+			id, ok := instance.Attributes["id"].(string)
+			if computeID, has := importIDs[resource.Type]; has {
+				id, ok = computeID(instance.Attributes), true
+			}
+			if !ok || id == "" {
+				reportWarning(fmt.Sprintf("skipping %v: the resource has no ID", address))
+				continue
+			}
+
+			file.Resources = append(file.Resources, importResource{Type: typ, Name: name, ID: id})
+		}
+	}
+	return file
+}
+
+// importType returns the Pulumi type token for the given Terraform resource.
+func importType(source il.ProviderInfoSource, resource resourceStateV4) (string, error) {
+	registry, namespace, name := "registry.terraform.io", "hashicorp", impliedProvider(resource.Type)
+	if match := providerAddressRegexp.FindStringSubmatch(resource.ProviderConfig); match != nil {
+		if parts := strings.Split(match[1], "/"); len(parts) == 3 {
+			registry, namespace, name = parts[0], parts[1], parts[2]
+		}
+	}
+
+	info, err := source.GetProviderInfo(registry, namespace, name, "")
+	if err != nil {
+		return "", err
+	}
+	if resourceInfo, ok := info.Resources[resource.Type]; ok && resourceInfo.Tok != "" {
+		return string(resourceInfo.Tok), nil
+	}
+	return "", fmt.Errorf("provider %v has no Pulumi type for %v", name, resource.Type)
+}
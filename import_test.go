@@ -0,0 +1,84 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tfbridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testProviderInfoSource returns the same provider information for every provider.
+type testProviderInfoSource struct {
+	info *tfbridge.ProviderInfo
+}
+
+func (s testProviderInfoSource) GetProviderInfo(
+	registry, namespace, name, version string) (*tfbridge.ProviderInfo, error) {
+	return s.info, nil
+}
+
+func TestImportResources(t *testing.T) {
+	t.Parallel()
+
+	source := testProviderInfoSource{info: &tfbridge.ProviderInfo{
+		Name: "aws",
+		Resources: map[string]*tfbridge.ResourceInfo{
+			"aws_instance": {Tok: "aws:ec2/instance:Instance"},
+			"aws_vpc":      {Tok: "aws:ec2/vpc:Vpc"},
+			"aws_subnet":   {Tok: "aws:ec2/subnet:Subnet"},
+		},
+	}}
+
+	const stateJSON = `{
+    "version": 4,
+    "resources": [
+        {
+            "mode": "managed", "type": "aws_instance", "name": "web_server",
+            "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+            "instances": [{"attributes": {"id": "i-0123"}}]
+        },
+        {
+            "mode": "managed", "type": "aws_subnet", "name": "private_subnet", "each": "list",
+            "instances": [
+                {"index_key": 0, "attributes": {"id": "subnet-0"}},
+                {"index_key": 1, "attributes": {"id": "subnet-1"}}
+            ]
+        },
+        {
+            "mode": "managed", "type": "aws_vpc", "name": "by_region", "each": "map",
+            "instances": [{"index_key": "us-west-2", "attributes": {"id": "vpc-west"}}]
+        },
+        {
+            "mode": "managed", "type": "aws_vpc", "name": "main",
+            "instances": [{"attributes": {"id": "vpc-main"}}]
+        }
+    ]
+}`
+	var state stateV4
+	require.NoError(t, json.Unmarshal([]byte(stateJSON), &state))
+
+	imports := importResources(source, state)
+	assert.Equal(t, []importResource{
+		{Type: "aws:ec2/instance:Instance", Name: "web_server", ID: "i-0123"},
+		{Type: "aws:ec2/subnet:Subnet", Name: "private_subnet-0", ID: "subnet-0"},
+		{Type: "aws:ec2/subnet:Subnet", Name: "private_subnet-1", ID: "subnet-1"},
+		{Type: "aws:ec2/vpc:Vpc", Name: "by_region-us-west-2", ID: "vpc-west"},
+		{Type: "aws:ec2/vpc:Vpc", Name: "main", ID: "vpc-main"},
+	}, imports.Resources)
+}
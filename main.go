@@ -16,64 +16,197 @@ package main
 
 import (
 	"archive/tar"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/convert"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/il"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/pulumi/tf2pulumi/version"
 )
 
+// memoryFileName is the name given to a configuration that is converted from memory, e.g. when --stdin is set.
+const memoryFileName = "/main.tf"
+
 func main() {
 	var opts convert.Options
 	resourceNameProperty, filterAutoNames, tarout := "", false, false
-
-	os.Stderr.WriteString("Warning: tf2pulumi is deprecated and no longer maintained. The functionality is now " +
-		"available from the Pulumi CLI's `pulumi convert --from terraform` command. See " +
-		"https://www.pulumi.com/docs/using-pulumi/adopting-pulumi/migrating-to-pulumi/from-terraform/ " +
-		"for more information on migrating from Terraform.\n\n")
+	stdin, stdout, listProvidersOnly, dryRun, emitPatch := false, false, false, false, false
+	var excludeDirs, only []string
+	quiet, generateProject, noSchemaCache := false, false, false
 
 	rootCmd := &cobra.Command{
-		Use:   "tf2pulumi",
+		Use:   "tf2pulumi [dir...]",
 		Short: "tf2pulumi converts Terraform configuration to a Pulumi TypeScript program",
 		Long: `A converter that takes Terraform configuration as input and produces a
-Pulumi TypeScript program that describes the same resource graph.`,
+Pulumi TypeScript program that describes the same resource graph.
 
+By default, the configuration in the current directory is converted and the
+generated program is written to the current directory. If one or more
+directories are given, each is converted independently and its program is
+written to a subdirectory named after the source directory.`,
+
+		Args:          cobra.ArbitraryArgs,
 		SilenceErrors: true,
 		SilenceUsage:  true,
 
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Unless disabled, cache the provider information fetched from plugins across invocations.
+			if !noSchemaCache && opts.ProviderInfoSource == nil {
+				if cacheDir, err := os.UserCacheDir(); err == nil {
+					opts.ProviderInfoSource = newDiskProviderInfoSource(
+						filepath.Join(cacheDir, "tf2pulumi", "provider-info"), il.PluginProviderInfoSource)
+				}
+			}
+			if err := validateDiagnosticsFormat(); err != nil {
+				return err
+			}
+
+			// JSON diagnostics may be written to stderr, so nothing else may be written there.
+			if diagnosticsFormat != diagnosticsFormatJSON {
+				os.Stderr.WriteString("Warning: tf2pulumi is deprecated and no longer maintained. The functionality " +
+					"is now available from the Pulumi CLI's `pulumi convert --from terraform` command. See " +
+					"https://www.pulumi.com/docs/using-pulumi/adopting-pulumi/migrating-to-pulumi/from-terraform/ " +
+					"for more information on migrating from Terraform.\n\n")
+			}
+			return nil
+		},
+
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if resourceNameProperty != "" && filterAutoNames {
 				return errors.New(
 					"exactly one of --filter-resource-names or --filter-auto-names may be specified")
 			}
 
+			outputModes := 0
+			for _, set := range []bool{tarout, stdout, dryRun, emitPatch} {
+				if set {
+					outputModes++
+				}
+			}
+			if outputModes > 1 {
+				return errors.New("at most one of --tar, --stdout, --dry-run, or --emit-patch may be specified")
+			}
+			if stdin && len(args) != 0 {
+				return errors.New("source directories may not be specified with --stdin")
+			}
+			if generateProject {
+				if err := validateProjectLanguage(opts.TargetLanguage); err != nil {
+					return err
+				}
+			}
+
 			opts.FilterResourceNames = resourceNameProperty != "" || filterAutoNames
+
+			// When emitting a patch, keep a copy of the diagnostics so that they can be included in its report.
+			if emitPatch {
+				diagnosticsLog = &bytes.Buffer{}
+			}
 			opts.ResourceNameProperty = resourceNameProperty
 
-			files, diags, err := convert.Convert(opts)
+			// If requested, read a single configuration file from stdin into an in-memory filesystem and convert
+			// that instead of the current working directory.
+			if stdin {
+				contents, err := ioutil.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("reading stdin: %w", err)
+				}
+				root, err := memoryRoot(contents)
+				if err != nil {
+					return err
+				}
+				opts.Root = root
+			}
+
+			roots, err := sourceRoots(opts, args, excludeDirs)
 			if err != nil {
 				return err
 			}
-			if len(diags.All) > 0 {
-				if err := diags.NewDiagnosticWriter(os.Stderr, 0, true).WriteDiagnostics(diags.All); err != nil {
-					return err
+
+			// If requested, restrict each configuration to the given items and their dependencies.
+			if len(only) != 0 {
+				for i, root := range roots {
+					if roots[i], err = pruneRoot(root, only); err != nil {
+						return err
+					}
 				}
 			}
 
-			if tarout {
-				w := tar.NewWriter(os.Stdout)
-				for filename, contents := range files {
-					if err := w.WriteHeader(&tar.Header{
-						Name: filename,
-						Mode: 0600,
-					}); err != nil {
+			if listProvidersOnly {
+				return listProviders(roots)
+			}
+
+			// If the user explicitly chose a Terraform version, make sure that the configuration agrees before
+			// attempting to convert it.
+			if cmd.Flags().Changed("terraform-version") {
+				targets, targetName, err := parseTerraformVersion(opts.TerraformVersion)
+				if err != nil {
+					return fmt.Errorf("invalid --terraform-version %q: %w", opts.TerraformVersion, err)
+				}
+				for _, root := range roots {
+					if err := checkRoot(root, targets, targetName); err != nil {
 						return err
 					}
-					if _, err := w.Write(contents); err != nil {
+				}
+			}
+
+			progress := progressWriter(quiet)
+
+			var files map[string][]byte
+			if len(args) == 0 {
+				opts.Root = roots[0]
+				fmt.Fprintf(progress, "Converting Terraform configuration...\n")
+				converted, diags, err := convert.Convert(opts)
+				if err != nil {
+					return err
+				}
+				if err := writeDiagnostics(diags); err != nil {
+					return err
+				}
+				files = converted
+			} else {
+				converted, err := convertRoots(opts, args, roots, progress)
+				if err != nil {
+					return err
+				}
+				files = converted
+			}
+
+			if generateProject {
+				if err := addProjectFiles(files, roots, args, opts.TargetLanguage); err != nil {
+					return err
+				}
+			}
+
+			if tarout {
+				return writeTar(os.Stdout, files)
+			}
+
+			if dryRun {
+				return reportFiles(os.Stdout, files)
+			}
+
+			if emitPatch {
+				return writePatch(os.Stdout, files, diagnosticsLog.Bytes())
+			}
+
+			if stdout {
+				if len(files) > 1 {
+					return fmt.Errorf("--stdout requires a single output file, but the conversion produced %d; "+
+						"use --tar instead", len(files))
+				}
+				for _, contents := range files {
+					if _, err := os.Stdout.Write(contents); err != nil {
 						return err
 					}
 				}
@@ -81,6 +214,11 @@ Pulumi TypeScript program that describes the same resource graph.`,
 			}
 
 			for filename, contents := range files {
+				if dir := filepath.Dir(filename); dir != "." {
+					if err := os.MkdirAll(dir, 0700); err != nil {
+						return err
+					}
+				}
 				if err := ioutil.WriteFile(filename, contents, 0600); err != nil {
 					return err
 				}
@@ -100,6 +238,32 @@ Pulumi TypeScript program that describes the same resource graph.`,
 		"annotate the generated code with original source locations for each resource")
 	flag.BoolVar(&tarout, "tar", false,
 		"generate a TAR archive to stdout instead of writing to the filesystem")
+	flag.BoolVar(&stdin, "stdin", false,
+		"read a single Terraform configuration file from stdin instead of the current directory")
+	flag.BoolVar(&stdout, "stdout", false,
+		"write the generated program to stdout instead of the filesystem; the program must be a single file")
+	flag.BoolVar(&listProvidersOnly, "list-providers", false,
+		"print the providers referenced by the configuration and the plugins they require instead of converting")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"report the files that would be written, and whether they already exist, without writing them")
+	flag.BoolVar(&emitPatch, "emit-patch", false,
+		"write a unified diff against the existing output, preceded by a report of the files and diagnostics, "+
+			"to stdout instead of writing to the filesystem")
+	flag.BoolVar(&generateProject, "generate-project", false,
+		"also generate Pulumi.yaml and the language's project files, unless they already exist")
+	flag.StringVar(&diagnosticsFormat, "diagnostics-format", diagnosticsFormatText,
+		"sets the format of reported diagnostics; one of \"text\" or \"json\"")
+	flag.StringVar(&diagnosticsFile, "diagnostics-file", "",
+		"when --diagnostics-format=json is set, writes the diagnostics to the given file instead of stderr")
+	flag.StringSliceVar(&only, "only", nil,
+		"converts only the given comma-separated resources, data sources, modules, locals, variables, or outputs "+
+			"(e.g. aws_instance.web,module.vpc) and the items they depend on")
+	flag.BoolVar(&noSchemaCache, "no-schema-cache", false,
+		"always fetch provider information from the provider plugins instead of using the on-disk cache")
+	flag.BoolVar(&quiet, "quiet", false,
+		"suppresses the progress messages written to a terminal as each source directory's conversion begins")
+	flag.StringArrayVar(&excludeDirs, "exclude-dir", nil,
+		"skips source directories matching the given gitignore-style pattern; may be specified multiple times")
 	flag.StringVar(&resourceNameProperty, "filter-resource-names", "",
 		"when set, the property with the given key will be removed from all resources")
 	flag.BoolVar(&filterAutoNames, "filter-auto-names", false,
@@ -110,6 +274,9 @@ Pulumi TypeScript program that describes the same resource graph.`,
 		"sets the language SDK version to target")
 	flag.StringVar(&opts.TerraformVersion, "terraform-version", "11",
 		"sets the Terraform version targeted by the source config")
+	rootCmd.AddCommand(newFuzzCommand(&opts))
+	rootCmd.AddCommand(newDevCommand(&opts))
+	rootCmd.AddCommand(newImportCommand(&opts))
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
 		Short: "Print the version number of tf2pulumi",
@@ -119,8 +286,286 @@ Pulumi TypeScript program that describes the same resource graph.`,
 		},
 	})
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	err := rootCmd.Execute()
+	reported := err != nil && collectJSONError(err)
+	if ferr := flushJSONDiagnostics(); ferr != nil && err == nil {
+		err = ferr
+	}
+	if err != nil {
+		if !reported {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
 		os.Exit(-1)
 	}
 }
+
+// sourceRoots returns the filesystems holding the configurations to convert: the in-memory filesystem populated from
+// stdin, the given directories, or the current working directory, in that order of preference. Paths listed in each
+// directory's ignore files or matched by excludeDirs are hidden from the returned filesystems.
+func sourceRoots(opts convert.Options, dirs, excludeDirs []string) ([]afero.Fs, error) {
+	switch {
+	case opts.Root != nil:
+		return []afero.Fs{opts.Root}, nil
+	case len(dirs) == 0:
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		root, err := sourceRoot(cwd, excludeDirs)
+		if err != nil {
+			return nil, err
+		}
+		return []afero.Fs{root}, nil
+	default:
+		roots := make([]afero.Fs, len(dirs))
+		for i, dir := range dirs {
+			root, err := sourceRoot(dir, excludeDirs)
+			if err != nil {
+				return nil, err
+			}
+			roots[i] = root
+		}
+		return roots, nil
+	}
+}
+
+// sourceRoot returns a filesystem rooted at the given source directory that hides the paths listed in the directory's
+// ignore files or matched by excludeDirs. If dir does not exist or is not a directory, the filesystem is returned
+// without reading any ignore files; convertRoots reports such directories as failures.
+func sourceRoot(dir string, excludeDirs []string) (afero.Fs, error) {
+	base := afero.NewBasePathFs(afero.NewOsFs(), dir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return base, nil
+	}
+
+	root, err := newIgnoreFs(base, excludeDirs)
+	if err != nil {
+		return nil, fmt.Errorf("%v: reading ignore files: %w", dir, err)
+	}
+	return root, nil
+}
+
+// memoryRoot returns an in-memory filesystem that holds a single configuration file with the given contents.
+func memoryRoot(contents []byte) (afero.Fs, error) {
+	root := afero.NewMemMapFs()
+	if err := afero.WriteFile(root, memoryFileName, contents, 0600); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// diagnosticsLog, if non-nil, receives an uncolored copy of every diagnostic written by writeDiagnostics.
+var diagnosticsLog *bytes.Buffer
+
+// writeDiagnostics writes any diagnostics produced by a conversion to stderr, or collects them if diagnostics are
+// being reported as JSON.
+func writeDiagnostics(diags convert.Diagnostics) error {
+	if len(diags.All) == 0 {
+		return nil
+	}
+	if diagnosticsLog != nil {
+		if err := diags.NewDiagnosticWriter(diagnosticsLog, 0, false).WriteDiagnostics(diags.All); err != nil {
+			return err
+		}
+	}
+	if collectJSONDiagnostics(diags.All) {
+		return nil
+	}
+	return diags.NewDiagnosticWriter(os.Stderr, 0, true).WriteDiagnostics(diags.All)
+}
+
+// convertRoots converts each of the given Terraform directories independently; roots holds the source filesystem for
+// each directory. The files generated for each directory are placed in a subdirectory named after the last element of
+// its absolute path. Provider information and schemas are shared between the conversions, and a summary of the results is written to stderr
+// once every directory has been processed; if diagnostics are reported as JSON, failures are collected as diagnostics
+// instead. A line is written to progress as each directory's conversion begins.
+func convertRoots(opts convert.Options, dirs []string, roots []afero.Fs,
+	progress io.Writer) (map[string][]byte, error) {
+
+	outDirs, sources := make([]string, len(dirs)), make(map[string]string, len(dirs))
+	for i, dir := range dirs {
+		outDir, err := outputDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := sources[outDir]; ok {
+			return nil, fmt.Errorf("source directories %v and %v would both be written to %v", other, dir, outDir)
+		}
+		outDirs[i], sources[outDir] = outDir, dir
+	}
+
+	if opts.PackageCache == nil {
+		opts.PackageCache = pcl.NewPackageCache()
+	}
+	if opts.ProviderInfoSource == nil {
+		opts.ProviderInfoSource = il.PluginProviderInfoSource
+	}
+	opts.ProviderInfoSource = il.NewCachingProviderInfoSource(opts.ProviderInfoSource)
+
+	files, summary, failed := map[string][]byte{}, make([]string, 0, len(dirs)), 0
+	for i, dir := range dirs {
+		fmt.Fprintf(progress, "Converting %v (%d of %d)...\n", dir, i+1, len(dirs))
+
+		info, err := os.Stat(dir)
+		if err == nil && !info.IsDir() {
+			err = fmt.Errorf("%v is not a directory", dir)
+		}
+		if err != nil {
+			failed++
+			summary = append(summary, fmt.Sprintf("%v: %v", dir, err))
+			collectJSONError(fmt.Errorf("%v: %w", dir, err))
+			continue
+		}
+
+		opts.Root = roots[i]
+		converted, diags, err := convert.Convert(opts)
+		if werr := writeDiagnostics(diags); werr != nil {
+			return nil, werr
+		}
+		switch {
+		case err != nil:
+			failed++
+			summary = append(summary, fmt.Sprintf("%v: %v", dir, err))
+			collectJSONError(fmt.Errorf("%v: %w", dir, err))
+		case diags.All.HasErrors():
+			failed++
+			summary = append(summary, fmt.Sprintf("%v: failed with %d diagnostic(s)", dir, len(diags.All)))
+		default:
+			outDir := outDirs[i]
+			for filename, contents := range converted {
+				files[filepath.Join(outDir, filename)] = contents
+			}
+			summary = append(summary, fmt.Sprintf("%v: converted %d file(s) to %v", dir, len(converted), outDir))
+		}
+	}
+
+	// When diagnostics are reported as JSON, the failures above were collected as diagnostics instead.
+	if diagnosticsFormat != diagnosticsFormatJSON {
+		fmt.Fprintf(os.Stderr, "\nConverted %d of %d directories:\n", len(dirs)-failed, len(dirs))
+		for _, line := range summary {
+			fmt.Fprintf(os.Stderr, "  %v\n", line)
+		}
+	}
+
+	if failed != 0 {
+		return nil, fmt.Errorf("failed to convert %d of %d directories", failed, len(dirs))
+	}
+	return files, nil
+}
+
+// outputDir returns the name of the subdirectory that receives the files generated for the given source directory: the
+// last element of its absolute path. Source directories without such an element, such as the filesystem root, are
+// rejected.
+func outputDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(abs)
+	if name == string(filepath.Separator) || name == "." || name == ".." {
+		return "", fmt.Errorf("cannot name an output directory after source directory %v", dir)
+	}
+	return name, nil
+}
+
+// addProjectFiles adds the project files for each converted source root to files. Source roots are matched with the
+// source directories the same way as in convertRoots; if no directories were given, the project is named after the
+// current working directory. Project files that already exist are left alone.
+func addProjectFiles(files map[string][]byte, roots []afero.Fs, dirs []string, language string) error {
+	for i, root := range roots {
+		outDir, name := "", ""
+		if len(dirs) != 0 {
+			dir, err := outputDir(dirs[i])
+			if err != nil {
+				return err
+			}
+			outDir, name = dir, dir
+		} else {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			name = filepath.Base(cwd)
+		}
+
+		// Diagnostics were already reported by the conversion.
+		providers, _ := findProviders(root)
+		project, err := projectFiles(name, language, providers)
+		if err != nil {
+			return err
+		}
+		for filename, contents := range project {
+			path := filepath.Join(outDir, filename)
+			if _, ok := files[path]; ok {
+				continue
+			}
+			if _, err := os.Stat(path); err == nil {
+				continue
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+			files[path] = contents
+		}
+	}
+	return nil
+}
+
+// progressWriter returns the writer that receives progress messages. Progress is written to stderr only if stderr is a
+// terminal, quiet is not set, and diagnostics are not reported as JSON; otherwise progress messages are discarded.
+func progressWriter(quiet bool) io.Writer {
+	if quiet || diagnosticsFormat == diagnosticsFormatJSON || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return ioutil.Discard
+	}
+	return os.Stderr
+}
+
+// reportFiles writes a summary of the files that would be written to the filesystem to w. Each line lists a file's
+// path, its size, and whether it would overwrite an existing file.
+func reportFiles(w io.Writer, files map[string][]byte) error {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		action := "create"
+		if _, err := os.Stat(filename); err == nil {
+			action = "overwrite"
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%-9s %s (%d bytes)\n", action, filename, len(files[filename])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTar writes the given files to w as a TAR archive. Files are written in name order so that the output is stable,
+// and each file is dropped from the map once it has been written so that its contents can be reclaimed.
+func writeTar(w io.Writer, files map[string][]byte) error {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	tw := tar.NewWriter(w)
+	for _, filename := range filenames {
+		contents := files[filename]
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filename,
+			Mode:     0600,
+			Size:     int64(len(contents)),
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(contents); err != nil {
+			return err
+		}
+		delete(files, filename)
+	}
+	return tw.Close()
+}
@@ -0,0 +1,247 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// configItem is a single addressable item in a configuration: a resource, data source, module call, variable, local
+// value, or output.
+type configItem struct {
+	parts []configPart
+	deps  []string
+	kept  bool
+}
+
+// configPart is the source text of a configItem in one file. An item has a part for its definition and one for each
+// override of it.
+type configPart struct {
+	file *hcl.File
+	rng  hcl.Range
+}
+
+// isOverrideFile returns true if the named configuration file is a Terraform override file, e.g. override.tf or
+// network_override.tf. The blocks in override files are merged into the blocks with the same addresses in the other
+// files.
+func isOverrideFile(filename string) bool {
+	name := strings.TrimSuffix(strings.TrimSuffix(path.Base(filename), ".json"), ".tf")
+	return name == "override" || strings.HasSuffix(name, "_override")
+}
+
+// pruneRoot returns a view of the given root in which the top-level configuration files only contain the items with
+// the given addresses, the items they transitively depend on, and the outputs that refer only to those items.
+// Provider and terraform blocks are always kept, as are the items they reference. Blocks in override files are kept
+// or removed together with the items they override. Files in subdirectories, such as local modules, are left as-is.
+func pruneRoot(root afero.Fs, addresses []string) (afero.Fs, error) {
+	files, diags := parseConfigFiles(root)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Index the override files last so that their blocks are merged into the items they override.
+	sort.SliceStable(files, func(i, j int) bool {
+		return !isOverrideFile(files[i].Body.MissingItemRange().Filename) &&
+			isOverrideFile(files[j].Body.MissingItemRange().Filename)
+	})
+
+	items, outputs := map[string]*configItem{}, map[string]*configItem{}
+	add := func(m map[string]*configItem, address string, file *hcl.File, rng hcl.Range, deps []string) {
+		item, ok := m[address]
+		if !ok {
+			item = &configItem{}
+			m[address] = item
+		}
+		item.parts = append(item.parts, configPart{file: file, rng: rng})
+		item.deps = append(item.deps, deps...)
+	}
+
+	var blockDeps []string
+	for _, file := range files {
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			var address string
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				address = block.Labels[0] + "." + block.Labels[1]
+			case block.Type == "data" && len(block.Labels) == 2:
+				address = "data." + block.Labels[0] + "." + block.Labels[1]
+			case block.Type == "module" && len(block.Labels) == 1:
+				address = "module." + block.Labels[0]
+			case block.Type == "variable" && len(block.Labels) == 1:
+				address = "var." + block.Labels[0]
+			case block.Type == "output" && len(block.Labels) == 1:
+				add(outputs, "output."+block.Labels[0], file, block.Range(), bodyReferences(block.Body))
+				continue
+			case block.Type == "locals":
+				for name, attr := range block.Body.Attributes {
+					add(items, "local."+name, file, attr.SrcRange, expressionReferences(attr.Expr))
+				}
+				continue
+			case block.Type == "provider" || block.Type == "terraform":
+				blockDeps = append(blockDeps, bodyReferences(block.Body)...)
+				continue
+			default:
+				continue
+			}
+			add(items, address, file, block.Range(), bodyReferences(block.Body))
+		}
+	}
+
+	// Mark the requested items and everything they depend on. Outputs may be requested by name.
+	var visit func(address string)
+	visit = func(address string) {
+		item, ok := items[address]
+		if !ok || item.kept {
+			return
+		}
+		item.kept = true
+		for _, dep := range item.deps {
+			visit(dep)
+		}
+	}
+	for _, dep := range blockDeps {
+		visit(dep)
+	}
+	for _, address := range addresses {
+		if output, ok := outputs[address]; ok {
+			output.kept = true
+			for _, dep := range output.deps {
+				visit(dep)
+			}
+			continue
+		}
+		if _, ok := items[address]; !ok {
+			return nil, fmt.Errorf("--only: the configuration does not contain %v", address)
+		}
+		visit(address)
+	}
+
+	// Keep any output whose references are all kept.
+	for _, output := range outputs {
+		if output.kept {
+			continue
+		}
+		output.kept = true
+		for _, dep := range output.deps {
+			if item, ok := items[dep]; ok && !item.kept {
+				output.kept = false
+				break
+			}
+		}
+	}
+
+	// Remove the source text of everything that is not kept from each file.
+	removed := map[*hcl.File][]hcl.Range{}
+	for _, m := range []map[string]*configItem{items, outputs} {
+		for _, item := range m {
+			if !item.kept {
+				for _, part := range item.parts {
+					removed[part.file] = append(removed[part.file], part.rng)
+				}
+			}
+		}
+	}
+
+	pruned := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(root), afero.NewMemMapFs())
+	for file, ranges := range removed {
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start.Byte < ranges[j].Start.Byte })
+
+		var contents []byte
+		offset := 0
+		for _, rng := range ranges {
+			contents = append(contents, file.Bytes[offset:rng.Start.Byte]...)
+			offset = rng.End.Byte
+		}
+		contents = append(contents, file.Bytes[offset:]...)
+
+		filename := file.Body.(*hclsyntax.Body).SrcRange.Filename
+		if err := afero.WriteFile(pruned, "/"+filename, contents, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return pruned, nil
+}
+
+// bodyReferences returns the addresses of the configuration items referenced by the attributes of the given body and
+// its nested blocks.
+func bodyReferences(body *hclsyntax.Body) []string {
+	var refs []string
+	for _, attr := range body.Attributes {
+		refs = append(refs, expressionReferences(attr.Expr)...)
+
+		// Terraform 0.11 configurations spell depends_on entries as strings rather than as references.
+		if attr.Name == "depends_on" {
+			if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.CanIterateElements() {
+				for _, element := range value.AsValueSlice() {
+					if element.Type() == cty.String && element.IsKnown() && !element.IsNull() {
+						refs = append(refs, element.AsString())
+					}
+				}
+			}
+		}
+	}
+	for _, block := range body.Blocks {
+		refs = append(refs, bodyReferences(block.Body)...)
+	}
+	return refs
+}
+
+// expressionReferences returns the addresses of the configuration items referenced by the given expression, e.g.
+// "aws_vpc.main" for `aws_vpc.main.id` or "var.region" for `var.region`. References to count, each, path, self, and
+// terraform values are omitted.
+func expressionReferences(expr hclsyntax.Expression) []string {
+	var refs []string
+	for _, traversal := range expr.Variables() {
+		var names []string
+		for _, traverser := range traversal {
+			switch t := traverser.(type) {
+			case hcl.TraverseRoot:
+				names = append(names, t.Name)
+			case hcl.TraverseAttr:
+				names = append(names, t.Name)
+			}
+			if len(names) == 3 {
+				break
+			}
+		}
+
+		switch {
+		case len(names) < 2:
+			continue
+		case names[0] == "count" || names[0] == "each" || names[0] == "path" || names[0] == "self" ||
+			names[0] == "terraform":
+			continue
+		case names[0] == "data":
+			if len(names) == 3 {
+				refs = append(refs, strings.Join(names, "."))
+			}
+		default:
+			refs = append(refs, names[0]+"."+names[1])
+		}
+	}
+	return refs
+}
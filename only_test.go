@@ -0,0 +1,193 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const onlyTestConfig = `terraform {
+  required_version = ">= 0.12"
+}
+
+variable "region" {}
+
+variable "unused" {}
+
+provider "aws" {
+  region = var.region
+}
+
+locals {
+  cidr = "10.0.0.0/16"
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = local.cidr
+}
+
+resource "aws_subnet" "private" {
+  vpc_id = aws_vpc.main.id
+}
+
+resource "aws_s3_bucket" "logs" {}
+
+output "vpc_id" {
+  value = aws_vpc.main.id
+}
+
+output "bucket" {
+  value = aws_s3_bucket.logs.id
+}
+`
+
+func TestPruneRoot(t *testing.T) {
+	t.Parallel()
+
+	root := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(root, "/main.tf", []byte(onlyTestConfig), 0600))
+
+	pruned, err := pruneRoot(root, []string{"aws_subnet.private"})
+	require.NoError(t, err)
+
+	contents, err := afero.ReadFile(pruned, "/main.tf")
+	require.NoError(t, err)
+	text := string(contents)
+
+	// The requested resource and its dependencies are kept.
+	assert.Contains(t, text, `resource "aws_subnet" "private"`)
+	assert.Contains(t, text, `resource "aws_vpc" "main"`)
+	assert.Contains(t, text, `cidr = "10.0.0.0/16"`)
+
+	// Provider and terraform blocks are kept along with the variables they reference.
+	assert.Contains(t, text, "terraform {")
+	assert.Contains(t, text, `provider "aws"`)
+	assert.Contains(t, text, `variable "region"`)
+
+	// Outputs that only refer to kept items are kept.
+	assert.Contains(t, text, `output "vpc_id"`)
+
+	// Everything else is removed.
+	assert.NotContains(t, text, `variable "unused"`)
+	assert.NotContains(t, text, `resource "aws_s3_bucket" "logs"`)
+	assert.NotContains(t, text, `output "bucket"`)
+
+	// The original files are not modified.
+	original, err := afero.ReadFile(root, "/main.tf")
+	require.NoError(t, err)
+	assert.Equal(t, onlyTestConfig, string(original))
+
+	// The pruned configuration still parses.
+	_, diags := parseConfigFiles(pruned)
+	assert.False(t, diags.HasErrors(), "%v", diags)
+}
+
+func TestPruneRootUnknownAddress(t *testing.T) {
+	t.Parallel()
+
+	root := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(root, "/main.tf", []byte(onlyTestConfig), 0600))
+
+	_, err := pruneRoot(root, []string{"aws_instance.missing"})
+	assert.EqualError(t, err, "--only: the configuration does not contain aws_instance.missing")
+}
+
+func TestExpressionReferences(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		expr     string
+		expected []string
+	}{
+		{"aws_vpc.main.id", []string{"aws_vpc.main"}},
+		{"aws_subnet.private[0].id", []string{"aws_subnet.private"}},
+		{"data.aws_ami.ubuntu.id", []string{"data.aws_ami.ubuntu"}},
+		{"var.region", []string{"var.region"}},
+		{"local.cidr", []string{"local.cidr"}},
+		{"module.network.vpc_id", []string{"module.network"}},
+		{"count.index", nil},
+		{"each.key", nil},
+		{"path.module", nil},
+		{"self.id", nil},
+		{"terraform.workspace", nil},
+		{`"${var.prefix}-${aws_vpc.main.id}"`, []string{"var.prefix", "aws_vpc.main"}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.expr, func(t *testing.T) {
+			t.Parallel()
+
+			expr, diags := hclsyntax.ParseExpression([]byte(c.expr), "test.tf", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors(), "%v", diags)
+			assert.Equal(t, c.expected, expressionReferences(expr))
+		})
+	}
+}
+
+func TestPruneRootOverrides(t *testing.T) {
+	t.Parallel()
+
+	root := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(root, "/main.tf", []byte(`
+variable "ami" {}
+
+resource "aws_instance" "web" {
+  instance_type = "t2.micro"
+}
+
+resource "aws_s3_bucket" "logs" {}
+`), 0600))
+	require.NoError(t, afero.WriteFile(root, "/web_override.tf", []byte(`
+resource "aws_instance" "web" {
+  ami = var.ami
+}
+
+resource "aws_s3_bucket" "logs" {
+  acl = "private"
+}
+`), 0600))
+
+	pruned, err := pruneRoot(root, []string{"aws_instance.web"})
+	require.NoError(t, err)
+
+	mainFile, err := afero.ReadFile(pruned, "/main.tf")
+	require.NoError(t, err)
+	override, err := afero.ReadFile(pruned, "/web_override.tf")
+	require.NoError(t, err)
+
+	// The override of a kept item is kept, and the items it references are kept with it.
+	assert.Contains(t, string(override), `resource "aws_instance" "web"`)
+	assert.Contains(t, string(mainFile), `variable "ami"`)
+
+	// The override of a removed item is removed with it.
+	assert.NotContains(t, string(mainFile), `resource "aws_s3_bucket" "logs"`)
+	assert.NotContains(t, string(override), `resource "aws_s3_bucket" "logs"`)
+}
+
+func TestIsOverrideFile(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isOverrideFile("override.tf"))
+	assert.True(t, isOverrideFile("override.tf.json"))
+	assert.True(t, isOverrideFile("network_override.tf"))
+	assert.False(t, isOverrideFile("main.tf"))
+	assert.False(t, isOverrideFile("overrides.tf"))
+}
@@ -0,0 +1,112 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// writePatch writes the given files to w as a unified diff against the files currently on disk. Files that do not exist
+// yet are diffed against /dev/null, and files whose contents would not change are omitted.
+//
+// The diff is preceded by a report that lists the files the conversion produced and the diagnostics it reported.
+// Tools such as `git apply` ignore this preamble, so the output can be applied as-is or attached to a pull request.
+func writePatch(w io.Writer, files map[string][]byte, diagnostics []byte) error {
+	var manifest bytes.Buffer
+	if err := reportFiles(&manifest, files); err != nil {
+		return err
+	}
+
+	var report bytes.Buffer
+	report.WriteString("Converted by tf2pulumi.\n\nFiles:\n")
+	writeIndented(&report, manifest.String())
+	if len(diagnostics) != 0 {
+		report.WriteString("\nDiagnostics:\n")
+		writeIndented(&report, string(diagnostics))
+	}
+	report.WriteString("\n")
+	if _, err := w.Write(report.Bytes()); err != nil {
+		return err
+	}
+
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		name := filepath.ToSlash(filepath.Clean(filename))
+		diff := difflib.UnifiedDiff{
+			B:        splitLines(files[filename]),
+			FromFile: "a/" + name,
+			ToFile:   "b/" + name,
+			Context:  3,
+		}
+
+		header := fmt.Sprintf("diff --git a/%s b/%s\n", name, name)
+		existing, err := ioutil.ReadFile(filename)
+		switch {
+		case os.IsNotExist(err):
+			header += "new file mode 100644\n"
+			diff.FromFile = "/dev/null"
+		case err != nil:
+			return err
+		case bytes.Equal(existing, files[filename]):
+			continue
+		default:
+			diff.A = splitLines(existing)
+		}
+
+		if _, err := io.WriteString(w, header); err != nil {
+			return err
+		}
+		if len(diff.A) == 0 && len(diff.B) == 0 {
+			// An empty new file has no hunks.
+			continue
+		}
+		if err := difflib.WriteUnifiedDiff(w, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLines splits text into lines for diffing. Each line keeps its terminating newline. If the text does not end
+// with a newline, its last line is followed by the marker that tells `git apply` and patch(1) so.
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n\\ No newline at end of file\n"
+	return lines
+}
+
+// writeIndented writes each line of text to buf, indented by two spaces.
+func writeIndented(buf *bytes.Buffer, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Fprintf(buf, "  %s\n", line)
+	}
+}
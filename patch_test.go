@@ -0,0 +1,86 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{}, splitLines(nil))
+	assert.Equal(t, []string{"a\n", "b\n"}, splitLines([]byte("a\nb\n")))
+	assert.Equal(t, []string{"a\n", "b\n\\ No newline at end of file\n"}, splitLines([]byte("a\nb")))
+}
+
+// TestWritePatchApplies checks that `git apply` reproduces the converted files from the patch. It changes the working
+// directory, so it must not run in parallel.
+func TestWritePatchApplies(t *testing.T) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	existing := map[string]string{
+		"modified.ts":         "const a = 1;\nconst b = 2;\n",
+		"missing-newline.ts":  "const a = 1;",
+		"unchanged.ts":        "const a = 1;\n",
+		"nested/modified.py":  "a = 1\n",
+		"gains-no-newline.ts": "const a = 1;\n",
+	}
+	for name, contents := range existing {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+	}
+
+	files := map[string][]byte{
+		"modified.ts":         []byte("const a = 1;\nconst b = 3;\n"),
+		"missing-newline.ts":  []byte("const a = 1;\nconst b = 2;\n"),
+		"unchanged.ts":        []byte("const a = 1;\n"),
+		"nested/modified.py":  []byte("a = 2\n"),
+		"gains-no-newline.ts": []byte("const a = 2;"),
+		"new.ts":              []byte("const c = 3;\n"),
+		"new-no-newline.ts":   []byte("const c = 3;"),
+		"new-empty.ts":        []byte{},
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	var patch bytes.Buffer
+	require.NoError(t, writePatch(&patch, files, []byte("warning: something\n")))
+
+	cmd := exec.Command(git, "apply", "-")
+	cmd.Stdin = &patch
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git apply failed: %s", output)
+
+	for name, contents := range files {
+		actual, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, string(contents), string(actual), name)
+	}
+}
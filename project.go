@@ -0,0 +1,151 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/convert"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/il"
+)
+
+// projectRuntimes maps each target language for which projects can be generated to the runtime named in its
+// Pulumi.yaml. Go and Java are omitted: a runnable project for either needs the provider SDKs' versions (and, for Go,
+// a go.sum), which cannot be determined without resolving them.
+var projectRuntimes = map[string]string{
+	convert.LanguageTypescript: "nodejs",
+	convert.LanguagePython:     "python",
+	convert.LanguageCSharp:     "dotnet",
+	convert.LanguageYaml:       "yaml",
+}
+
+// validateProjectLanguage returns an error if projects cannot be generated for the given target language.
+func validateProjectLanguage(language string) error {
+	if _, ok := projectRuntimes[language]; !ok {
+		return fmt.Errorf("--generate-project is not supported for target language %q", language)
+	}
+	return nil
+}
+
+// projectFiles returns the Pulumi.yaml and language-specific project files for a converted program with the given
+// name, target language, and Terraform providers. A dependency on the Pulumi package for each provider is added to
+// the project files of languages that declare their dependencies up front.
+func projectFiles(name, language string, providers []string) (map[string][]byte, error) {
+	if err := validateProjectLanguage(language); err != nil {
+		return nil, err
+	}
+	runtime := projectRuntimes[language]
+
+	plugins := make([]string, len(providers))
+	for i, provider := range providers {
+		plugins[i] = il.GetPulumiProviderName(provider)
+	}
+
+	files := map[string][]byte{
+		// The name is taken from a directory name, so it is quoted; Go's quoted strings are valid YAML double-quoted
+		// scalars.
+		"Pulumi.yaml": []byte(fmt.Sprintf("name: %q\nruntime: %s\ndescription: Converted from Terraform by tf2pulumi\n",
+			name, runtime)),
+	}
+
+	switch language {
+	case convert.LanguageTypescript:
+		dependencies := map[string]string{"@pulumi/pulumi": "^3.0.0"}
+		for _, plugin := range plugins {
+			dependencies["@pulumi/"+plugin] = "latest"
+		}
+		packageJSON, err := marshalProjectJSON(map[string]interface{}{
+			"name":            name,
+			"main":            "index.ts",
+			"devDependencies": map[string]string{"@types/node": "^16.0.0", "typescript": "^4.0.0"},
+			"dependencies":    dependencies,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tsconfigJSON, err := marshalProjectJSON(map[string]interface{}{
+			"compilerOptions": map[string]interface{}{
+				"strict":                           true,
+				"outDir":                           "bin",
+				"target":                           "es2016",
+				"module":                           "commonjs",
+				"moduleResolution":                 "node",
+				"sourceMap":                        true,
+				"experimentalDecorators":           true,
+				"pretty":                           true,
+				"noFallthroughCasesInSwitch":       true,
+				"noImplicitReturns":                true,
+				"forceConsistentCasingInFileNames": true,
+			},
+			"files": []string{"index.ts"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		files["package.json"], files["tsconfig.json"] = packageJSON, tsconfigJSON
+	case convert.LanguagePython:
+		var requirements strings.Builder
+		requirements.WriteString("pulumi>=3.0.0,<4.0.0\n")
+		for _, plugin := range plugins {
+			fmt.Fprintf(&requirements, "pulumi-%s\n", plugin)
+		}
+		files["requirements.txt"] = []byte(requirements.String())
+	case convert.LanguageCSharp:
+		var csproj strings.Builder
+		csproj.WriteString(`<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net6.0</TargetFramework>
+    <Nullable>enable</Nullable>
+  </PropertyGroup>
+
+  <ItemGroup>
+    <PackageReference Include="Pulumi" Version="3.*" />
+`)
+		for _, plugin := range plugins {
+			fmt.Fprintf(&csproj, "    <PackageReference Include=\"Pulumi.%s\" Version=\"*\" />\n", dotnetPackageName(plugin))
+		}
+		csproj.WriteString("  </ItemGroup>\n\n</Project>\n")
+		files[name+".csproj"] = []byte(csproj.String())
+	}
+	return files, nil
+}
+
+// marshalProjectJSON returns the indented JSON encoding of the given value, terminated by a newline.
+func marshalProjectJSON(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dotnetPackageName returns the suffix of the NuGet package name for the given plugin, e.g. "AzureNative" for
+// "azure-native".
+func dotnetPackageName(plugin string) string {
+	parts := strings.Split(plugin, "-")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
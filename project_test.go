@@ -0,0 +1,46 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectFilesQuotesName(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"network", "app: v2", "#1", "-infra", "*prod", `say "hi"`} {
+		files, err := projectFiles(name, convert.LanguageYaml, nil)
+		require.NoError(t, err)
+
+		line := strings.SplitN(string(files["Pulumi.yaml"]), "\n", 2)[0]
+		expected := `name: "` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+		assert.Equal(t, expected, line)
+	}
+}
+
+func TestProjectFilesUnsupportedLanguages(t *testing.T) {
+	t.Parallel()
+
+	for _, language := range []string{convert.LanguageGo, convert.LanguageJava} {
+		_, err := projectFiles("network", language, nil)
+		assert.Error(t, err)
+	}
+}
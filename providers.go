@@ -0,0 +1,187 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/il"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// providerSchema describes the top-level blocks that may reference a provider or a module that references one.
+var providerSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+// moduleSourceSchema describes the source attribute of a module block.
+var moduleSourceSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "source"}},
+}
+
+// findProviders returns the names of the Terraform providers referenced by the configuration files at the root of the
+// given filesystem and by the local modules they call. Providers are found by looking at provider blocks and at the
+// explicit or implied provider of each resource and data source; no provider schemas are loaded. Modules whose
+// sources are outside of the filesystem, such as registry modules, are not scanned.
+func findProviders(root afero.Fs) ([]string, hcl.Diagnostics) {
+	providers, scanned := map[string]bool{}, map[string]bool{}
+	var diagnostics hcl.Diagnostics
+
+	var scan func(dir string)
+	scan = func(dir string) {
+		if scanned[dir] {
+			return
+		}
+		scanned[dir] = true
+
+		files, diags := parseConfigDir(root, dir)
+		diagnostics = append(diagnostics, diags...)
+		for _, file := range files {
+			content, _, _ := file.Body.PartialContent(providerSchema)
+			for _, block := range content.Blocks {
+				switch block.Type {
+				case "provider":
+					providers[block.Labels[0]] = true
+				case "resource", "data":
+					// Prefer an explicit provider reference (e.g. `provider = aws.west`) over the provider implied by
+					// the resource type.
+					attrs, _ := block.Body.JustAttributes()
+					if attr, ok := attrs["provider"]; ok {
+						if traversal, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+							providers[traversal.RootName()] = true
+							continue
+						}
+					}
+					providers[impliedProvider(block.Labels[0])] = true
+				case "module":
+					if child, ok := localModuleDir(dir, block); ok {
+						scan(child)
+					}
+				}
+			}
+		}
+	}
+	scan(".")
+
+	// The built-in terraform provider has no Pulumi equivalent.
+	delete(providers, "terraform")
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, diagnostics
+}
+
+// localModuleDir returns the directory of the local module called by the given module block in dir, relative to the
+// root of the configuration. It returns false if the module's source is not a local path or is outside of the root.
+func localModuleDir(dir string, block *hcl.Block) (string, bool) {
+	content, _, _ := block.Body.PartialContent(moduleSourceSchema)
+	attr, ok := content.Attributes["source"]
+	if !ok {
+		return "", false
+	}
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.Type().Equals(cty.String) || !value.IsKnown() || value.IsNull() {
+		return "", false
+	}
+
+	source := value.AsString()
+	if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return "", false
+	}
+	child := path.Join(dir, source)
+	if child == ".." || strings.HasPrefix(child, "../") {
+		return "", false
+	}
+	return child, true
+}
+
+// impliedProvider returns the provider implied by a resource type, e.g. "aws" for "aws_instance".
+func impliedProvider(typeName string) string {
+	if under := strings.Index(typeName, "_"); under != -1 {
+		return typeName[:under]
+	}
+	return typeName
+}
+
+// writeProviders prints the Pulumi plugins that correspond to the given Terraform providers along with the commands
+// necessary to install them.
+func writeProviders(w io.Writer, providers []string) error {
+	if len(providers) == 0 {
+		_, err := fmt.Fprintln(w, "No providers are referenced by the configuration.")
+		return err
+	}
+
+	plugins := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		plugin := il.GetPulumiProviderName(provider)
+		plugins = append(plugins, plugin)
+		if _, err := fmt.Fprintf(w, "%s (Terraform provider %q)\n", plugin, provider); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintln(w, "\nTo install the required plugins, run:"); err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		if _, err := fmt.Fprintf(w, "  pulumi plugin install resource %s\n", plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listProviders writes the set of providers referenced by the configurations in the given roots to stdout. Any
+// diagnostics encountered while scanning the configurations are written to stderr.
+func listProviders(roots []afero.Fs) error {
+	all := map[string]bool{}
+	for _, root := range roots {
+		providers, diags := findProviders(root)
+		if len(diags) != 0 && !collectJSONDiagnostics(diags) {
+			if err := hcl.NewDiagnosticTextWriter(os.Stderr, nil, 0, true).WriteDiagnostics(diags); err != nil {
+				return err
+			}
+		}
+		if diags.HasErrors() {
+			return errors.New("failed to scan the configuration for providers")
+		}
+		for _, provider := range providers {
+			all[provider] = true
+		}
+	}
+
+	providers := make([]string, 0, len(all))
+	for provider := range all {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return writeProviders(os.Stdout, providers)
+}
@@ -0,0 +1,75 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProviders(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"/main.tf": `
+resource "aws_instance" "web" {}
+
+module "network" {
+  source = "./modules/network"
+}
+
+module "vpc" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+
+module "shared" {
+  source = "../shared"
+}
+`,
+		"/extra.tf.json": `{"resource": {"google_storage_bucket": {"assets": {}}}}`,
+
+		// The random provider is only used inside a module, and the cloudflare provider only inside a module called
+		// by that module.
+		"/modules/network/main.tf": `
+resource "random_id" "suffix" {
+  byte_length = 4
+}
+
+module "dns" {
+  source = "../dns"
+}
+
+module "cycle" {
+  source = "./"
+}
+`,
+		"/modules/dns/main.tf.json": `{"provider": {"cloudflare": {}}}`,
+
+		// Directories that are not called as modules are not scanned.
+		"/examples/main.tf": `resource "azurerm_resource_group" "example" {}`,
+	}
+
+	root := afero.NewMemMapFs()
+	for name, contents := range files {
+		require.NoError(t, afero.WriteFile(root, name, []byte(contents), 0600))
+	}
+
+	providers, diags := findProviders(root)
+	require.False(t, diags.HasErrors(), "%v", diags)
+	assert.Equal(t, []string{"aws", "cloudflare", "google", "random"}, providers)
+}
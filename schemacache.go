@@ -0,0 +1,110 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tf2pulumi/il"
+	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tfbridge"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// diskProviderInfoSource is a ProviderInfoSource that caches the provider information returned by another source in
+// a directory on disk. Cache entries are keyed by the plugin's name and by the path, size, and modification time of
+// its executable, so installing a different version of a plugin invalidates its entry.
+type diskProviderInfoSource struct {
+	dir    string
+	source il.ProviderInfoSource
+}
+
+// newDiskProviderInfoSource returns a ProviderInfoSource that caches the results of source in the given directory.
+func newDiskProviderInfoSource(dir string, source il.ProviderInfoSource) il.ProviderInfoSource {
+	return &diskProviderInfoSource{dir: dir, source: source}
+}
+
+// cachePath returns the path of the cache entry for the named Terraform provider, or the empty string if the
+// provider's plugin cannot be found.
+func (s *diskProviderInfoSource) cachePath(name string) string {
+	pluginName := il.GetPulumiProviderName(name)
+	path, err := workspace.GetPluginPath(workspace.ResourcePlugin, pluginName, nil, nil)
+	if err != nil || path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano())))
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%x.json", pluginName, hash[:8]))
+}
+
+func (s *diskProviderInfoSource) GetProviderInfo(
+	registry, namespace, name, version string) (*tfbridge.ProviderInfo, error) {
+
+	path := s.cachePath(name)
+	if path == "" {
+		// Let the underlying source report the missing plugin.
+		return s.source.GetProviderInfo(registry, namespace, name, version)
+	}
+
+	if contents, err := ioutil.ReadFile(path); err == nil {
+		var cached tfbridge.MarshallableProviderInfo
+		if err := json.Unmarshal(contents, &cached); err == nil {
+			return cached.Unmarshal(), nil
+		}
+	}
+
+	info, err := s.source.GetProviderInfo(registry, namespace, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failing to write the cache is not fatal: the information will simply be fetched again next time.
+	_ = s.write(path, info)
+	return info, nil
+}
+
+// write writes the given provider information to the cache entry at path. The entry is written to a temporary file
+// first so that concurrent conversions never observe a partially-written entry.
+func (s *diskProviderInfoSource) write(path string, info *tfbridge.ProviderInfo) error {
+	contents, err := json.Marshal(tfbridge.MarshalProviderInfo(info))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(contents)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,45 @@
+import pulumi
+import pulumi_gcp as gcp
+
+default_dataset = gcp.bigquery.Dataset("default",
+    accesses=[
+        gcp.bigquery.DatasetAccessArgs(
+            role="OWNER",
+            special_group="projectOwners",
+        ),
+        gcp.bigquery.DatasetAccessArgs(
+            role="READER",
+            special_group="projectReaders",
+        ),
+    ],
+    dataset_id="example_dataset",
+    description="An example dataset",
+    friendly_name="example",
+    labels={
+        "env": "default",
+    },
+    location="US")
+default_table = gcp.bigquery.Table("default",
+    dataset_id=default_dataset.dataset_id,
+    labels={
+        "env": "default",
+    },
+    schema="""[
+  {
+    "name": "permalink",
+    "type": "STRING",
+    "mode": "NULLABLE",
+    "description": "The Permalink"
+  },
+  {
+    "name": "state",
+    "type": "STRING",
+    "mode": "NULLABLE",
+    "description": "State where the head office is located"
+  }
+]
+""",
+    table_id="example_table",
+    time_partitioning=gcp.bigquery.TableTimePartitioningArgs(
+        type="DAY",
+    ))
@@ -0,0 +1,49 @@
+import * as pulumi from "@pulumi/pulumi";
+import * as gcp from "@pulumi/gcp";
+
+// Originally defined at main.tf:1
+const defaultDataset = new gcp.bigquery.Dataset("default", {
+    accesses: [
+        {
+            role: "OWNER",
+            specialGroup: "projectOwners",
+        },
+        {
+            role: "READER",
+            specialGroup: "projectReaders",
+        },
+    ],
+    datasetId: "example_dataset",
+    description: "An example dataset",
+    friendlyName: "example",
+    labels: {
+        env: "default",
+    },
+    location: "US",
+});
+// Originally defined at main.tf:22
+const defaultTable = new gcp.bigquery.Table("default", {
+    datasetId: defaultDataset.datasetId,
+    labels: {
+        env: "default",
+    },
+    schema: `[
+  {
+    "name": "permalink",
+    "type": "STRING",
+    "mode": "NULLABLE",
+    "description": "The Permalink"
+  },
+  {
+    "name": "state",
+    "type": "STRING",
+    "mode": "NULLABLE",
+    "description": "State where the head office is located"
+  }
+]
+`,
+    tableId: "example_table",
+    timePartitioning: {
+        type: "DAY",
+    },
+});
@@ -56,3 +56,7 @@ func RunGCPTest(t *testing.T, dir string, opts ...terraform.TestOptionsFunc) {
 func TestRecordSet(t *testing.T) {
 	RunGCPTest(t, "record_set", terraform.Compile(false))
 }
+
+func TestBigQueryTable(t *testing.T) {
+	RunGCPTest(t, "bigquery_table", terraform.Compile(false))
+}